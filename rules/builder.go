@@ -0,0 +1,135 @@
+package rules
+
+import "fmt"
+
+// RuleSpec is a declarative, serializable description of a staticRule. It
+// exists so that other packages (such as rules/spec) can build rule graphs
+// from parsed documents without reaching into this package's unexported
+// rule types directly.
+type RuleSpec struct {
+	// Type selects the kind of rule to build: "equals", "equalsLiteral",
+	// "lessThan", "greaterThan", "regex", "exists", "numericEquals",
+	// "and", "or", "xor", "nand", "not", or "ref".
+	Type string
+	// Keys are the etcd keys the rule reads, for the leaf comparison
+	// types.
+	Keys []string
+	// Value is the literal compared against, for the literal comparison
+	// types.
+	Value *string
+	// Pattern is the regular expression source, for Type == "regex".
+	Pattern string
+	// Ref is the name of a rule registered in a RuleLibrary, for
+	// Type == "ref".
+	Ref string
+	// Children are the nested specs for the compound types "and", "or",
+	// "xor", "nand", and "not" (which takes exactly one child).
+	Children []RuleSpec
+}
+
+// RuleSet is a named collection of compiled rules, built independently of
+// any RuleLibrary so that an entire document can be compiled before it is
+// installed. Pass a RuleSet to RuleLibrary.ReplaceAll to install it
+// atomically.
+type RuleSet map[string]staticRule
+
+// NewRuleSet returns an empty RuleSet.
+func NewRuleSet() RuleSet {
+	return RuleSet{}
+}
+
+// Set registers rule under name within the set.
+func (rs RuleSet) Set(name string, rule staticRule) {
+	rs[name] = rule
+}
+
+// BuildRule compiles spec into a staticRule, binding any "ref" nodes to
+// library. A ref is looked up by name only when the rule is evaluated, so
+// a ref to a name not (yet) registered in library is not an error here.
+// It returns an error if spec describes an unknown rule type or is
+// otherwise malformed.
+func BuildRule(spec RuleSpec, attr Attributes, library *RuleLibrary) (staticRule, error) {
+	switch spec.Type {
+	case "equals":
+		return newEqualsRuleFactory().newRule(spec.Keys, attr), nil
+	case "equalsLiteral", "lessThan", "greaterThan", "numericEquals", "exists", "regex":
+		if len(spec.Keys) != 1 {
+			return nil, fmt.Errorf("rules: %q rule requires exactly one key, got %d", spec.Type, len(spec.Keys))
+		}
+		return buildSingleKeyRule(spec, attr)
+	case "ref":
+		return newSubRuleReferenceFactory(spec.Ref, library).newRule(spec.Keys, attr), nil
+	case "and", "or", "xor", "nand":
+		if len(spec.Children) == 0 {
+			return nil, fmt.Errorf("rules: %q rule requires at least one child", spec.Type)
+		}
+		nested, err := buildChildren(spec.Children, attr, library)
+		if err != nil {
+			return nil, err
+		}
+		csr := compoundStaticRule{nestedRules: nested}
+		switch spec.Type {
+		case "and":
+			return &andStaticRule{compoundStaticRule: csr}, nil
+		case "or":
+			return &orStaticRule{compoundStaticRule: csr}, nil
+		case "xor":
+			return &xorStaticRule{compoundStaticRule: csr}, nil
+		default:
+			return &nandStaticRule{compoundStaticRule: csr}, nil
+		}
+	case "not":
+		if len(spec.Children) != 1 {
+			return nil, fmt.Errorf("rules: \"not\" rule requires exactly one child, got %d", len(spec.Children))
+		}
+		nested, err := BuildRule(spec.Children[0], attr, library)
+		if err != nil {
+			return nil, err
+		}
+		return &notStaticRule{nested: nested}, nil
+	default:
+		return nil, fmt.Errorf("rules: unknown rule type %q", spec.Type)
+	}
+}
+
+// buildSingleKeyRule builds the leaf comparison types that each read
+// exactly one key. Callers must already have validated len(spec.Keys) == 1.
+func buildSingleKeyRule(spec RuleSpec, attr Attributes) (staticRule, error) {
+	switch spec.Type {
+	case "equalsLiteral":
+		return newEqualsLiteralRuleFactory(spec.Value).newRule(spec.Keys, attr), nil
+	case "lessThan":
+		return newLessThanLiteralRuleFactory(valueOrEmpty(spec.Value)).newRule(spec.Keys, attr), nil
+	case "greaterThan":
+		return newGreaterThanLiteralRuleFactory(valueOrEmpty(spec.Value)).newRule(spec.Keys, attr), nil
+	case "numericEquals":
+		return newNumericEqualsRuleFactory(valueOrEmpty(spec.Value)).newRule(spec.Keys, attr), nil
+	case "exists":
+		return newExistsRuleFactory().newRule(spec.Keys, attr), nil
+	default: // "regex"
+		factory, err := newRegexMatchRuleFactory(spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rules: invalid regex %q: %s", spec.Pattern, err)
+		}
+		return factory.newRule(spec.Keys, attr), nil
+	}
+}
+
+func buildChildren(specs []RuleSpec, attr Attributes, library *RuleLibrary) ([]staticRule, error) {
+	nested := make([]staticRule, 0, len(specs))
+	for _, childSpec := range specs {
+		child, err := BuildRule(childSpec, attr, library)
+		if err != nil {
+			return nil, err
+		}
+		nested = append(nested, child)
+	}
+	return nested, nil
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,42 @@
+package rules
+
+import "testing"
+
+func TestBuildRuleRejectsMissingKey(t *testing.T) {
+	literal := "x"
+	singleKeyTypes := []string{"equalsLiteral", "lessThan", "greaterThan", "numericEquals", "exists", "regex"}
+	for _, ruleType := range singleKeyTypes {
+		spec := RuleSpec{Type: ruleType, Value: &literal, Pattern: "x"}
+		if _, err := BuildRule(spec, nil, nil); err == nil {
+			t.Errorf("BuildRule(%q) with no keys: expected error, got nil", ruleType)
+		}
+	}
+}
+
+func TestBuildRuleRejectsTooManyKeys(t *testing.T) {
+	spec := RuleSpec{Type: "equalsLiteral", Keys: []string{"/a", "/b"}}
+	if _, err := BuildRule(spec, nil, nil); err == nil {
+		t.Error("BuildRule(equalsLiteral) with two keys: expected error, got nil")
+	}
+}
+
+func TestBuildRuleRejectsEmptyCompoundChildren(t *testing.T) {
+	for _, ruleType := range []string{"and", "or", "xor", "nand"} {
+		spec := RuleSpec{Type: ruleType}
+		if _, err := BuildRule(spec, nil, nil); err == nil {
+			t.Errorf("BuildRule(%q) with no children: expected error, got nil", ruleType)
+		}
+	}
+}
+
+func TestBuildRuleAcceptsValidSingleKeyRule(t *testing.T) {
+	literal := "x"
+	spec := RuleSpec{Type: "equalsLiteral", Keys: []string{"/key"}, Value: &literal}
+	rule, err := BuildRule(spec, nil, nil)
+	if err != nil {
+		t.Fatalf("BuildRule(equalsLiteral) with one key: unexpected error: %s", err)
+	}
+	if !rule.keyMatch("/key") {
+		t.Error("built rule does not match its own key")
+	}
+}
@@ -0,0 +1,295 @@
+package rules
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// lessThanLiteralRule is satisfied when the key's value, parsed as a
+// float, is less than the literal value.
+type lessThanLiteralRule struct {
+	baseRule
+	key   string
+	value string
+}
+
+type lessThanLiteralRuleFactory struct {
+	value string
+}
+
+func newLessThanLiteralRuleFactory(value string) ruleFactory {
+	factory := lessThanLiteralRuleFactory{
+		value: value,
+	}
+	return &factory
+}
+
+func (ltrf *lessThanLiteralRuleFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := lessThanLiteralRule{
+		baseRule: br,
+		key:      keys[0],
+		value:    ltrf.value,
+	}
+	return &r
+}
+
+func (ltr *lessThanLiteralRule) keyMatch(key string) bool {
+	return ltr.key == key
+}
+
+func (ltr *lessThanLiteralRule) requiredKeys() []string {
+	return []string{ltr.key}
+}
+
+func (ltr *lessThanLiteralRule) satisfiable(key string, value *string) bool {
+	if key != ltr.key || value == nil {
+		return false
+	}
+	actual, err := strconv.ParseFloat(*value, 64)
+	if err != nil {
+		return false
+	}
+	threshold, err := strconv.ParseFloat(ltr.value, 64)
+	if err != nil {
+		return false
+	}
+	return actual < threshold
+}
+
+func (ltr *lessThanLiteralRule) satisfied(api readAPI) (bool, error) {
+	value, err := api.get(ltr.key)
+	if err != nil {
+		return false, err
+	}
+	return ltr.satisfiable(ltr.key, value), nil
+}
+
+// greaterThanLiteralRule is satisfied when the key's value, parsed as a
+// float, is greater than the literal value.
+type greaterThanLiteralRule struct {
+	baseRule
+	key   string
+	value string
+}
+
+type greaterThanLiteralRuleFactory struct {
+	value string
+}
+
+func newGreaterThanLiteralRuleFactory(value string) ruleFactory {
+	factory := greaterThanLiteralRuleFactory{
+		value: value,
+	}
+	return &factory
+}
+
+func (gtrf *greaterThanLiteralRuleFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := greaterThanLiteralRule{
+		baseRule: br,
+		key:      keys[0],
+		value:    gtrf.value,
+	}
+	return &r
+}
+
+func (gtr *greaterThanLiteralRule) keyMatch(key string) bool {
+	return gtr.key == key
+}
+
+func (gtr *greaterThanLiteralRule) requiredKeys() []string {
+	return []string{gtr.key}
+}
+
+func (gtr *greaterThanLiteralRule) satisfiable(key string, value *string) bool {
+	if key != gtr.key || value == nil {
+		return false
+	}
+	actual, err := strconv.ParseFloat(*value, 64)
+	if err != nil {
+		return false
+	}
+	threshold, err := strconv.ParseFloat(gtr.value, 64)
+	if err != nil {
+		return false
+	}
+	return actual > threshold
+}
+
+func (gtr *greaterThanLiteralRule) satisfied(api readAPI) (bool, error) {
+	value, err := api.get(gtr.key)
+	if err != nil {
+		return false, err
+	}
+	return gtr.satisfiable(gtr.key, value), nil
+}
+
+// regexMatchRule is satisfied when the key's value matches a compiled
+// regular expression.
+type regexMatchRule struct {
+	baseRule
+	key     string
+	pattern *regexp.Regexp
+}
+
+type regexMatchRuleFactory struct {
+	pattern *regexp.Regexp
+}
+
+func newRegexMatchRuleFactory(pattern string) (ruleFactory, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	factory := regexMatchRuleFactory{
+		pattern: compiled,
+	}
+	return &factory, nil
+}
+
+func (rmrf *regexMatchRuleFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := regexMatchRule{
+		baseRule: br,
+		key:      keys[0],
+		pattern:  rmrf.pattern,
+	}
+	return &r
+}
+
+func (rmr *regexMatchRule) keyMatch(key string) bool {
+	return rmr.key == key
+}
+
+func (rmr *regexMatchRule) requiredKeys() []string {
+	return []string{rmr.key}
+}
+
+func (rmr *regexMatchRule) satisfiable(key string, value *string) bool {
+	if key != rmr.key || value == nil {
+		return false
+	}
+	return rmr.pattern.MatchString(*value)
+}
+
+func (rmr *regexMatchRule) satisfied(api readAPI) (bool, error) {
+	value, err := api.get(rmr.key)
+	if err != nil {
+		return false, err
+	}
+	return rmr.satisfiable(rmr.key, value), nil
+}
+
+// existsRule is satisfied when the key is present in etcd, regardless of
+// its value.
+type existsRule struct {
+	baseRule
+	key string
+}
+
+type existsRuleFactory struct{}
+
+func newExistsRuleFactory() ruleFactory {
+	factory := existsRuleFactory{}
+	return &factory
+}
+
+func (erf *existsRuleFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := existsRule{
+		baseRule: br,
+		key:      keys[0],
+	}
+	return &r
+}
+
+func (er *existsRule) keyMatch(key string) bool {
+	return er.key == key
+}
+
+func (er *existsRule) requiredKeys() []string {
+	return []string{er.key}
+}
+
+func (er *existsRule) satisfiable(key string, value *string) bool {
+	return key == er.key && value != nil
+}
+
+func (er *existsRule) satisfied(api readAPI) (bool, error) {
+	value, err := api.get(er.key)
+	if err != nil {
+		return false, err
+	}
+	return value != nil, nil
+}
+
+// numericEqualsRule is satisfied when the key's value, parsed as a float,
+// equals the literal value, so "1.0" and "1" are considered equal.
+type numericEqualsRule struct {
+	baseRule
+	key   string
+	value string
+}
+
+type numericEqualsRuleFactory struct {
+	value string
+}
+
+func newNumericEqualsRuleFactory(value string) ruleFactory {
+	factory := numericEqualsRuleFactory{
+		value: value,
+	}
+	return &factory
+}
+
+func (nerf *numericEqualsRuleFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := numericEqualsRule{
+		baseRule: br,
+		key:      keys[0],
+		value:    nerf.value,
+	}
+	return &r
+}
+
+func (ner *numericEqualsRule) keyMatch(key string) bool {
+	return ner.key == key
+}
+
+func (ner *numericEqualsRule) requiredKeys() []string {
+	return []string{ner.key}
+}
+
+func (ner *numericEqualsRule) satisfiable(key string, value *string) bool {
+	if key != ner.key || value == nil {
+		return false
+	}
+	actual, err := strconv.ParseFloat(*value, 64)
+	if err != nil {
+		return false
+	}
+	expected, err := strconv.ParseFloat(ner.value, 64)
+	if err != nil {
+		return false
+	}
+	return actual == expected
+}
+
+func (ner *numericEqualsRule) satisfied(api readAPI) (bool, error) {
+	value, err := api.get(ner.key)
+	if err != nil {
+		return false, err
+	}
+	return ner.satisfiable(ner.key, value), nil
+}
@@ -0,0 +1,89 @@
+// Package spec parses declarative rule documents (YAML or JSON) into the
+// ruleFactory/staticRule graph defined by the rules package, analogous to a
+// schema+loader+framework split: a Document is the schema, ParseYAML and
+// ParseJSON are the loader, and rules.BuildRule is the framework that turns it into live
+// rules.
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mirskifa/go-etcd-rules/rules"
+	"gopkg.in/yaml.v2"
+)
+
+// Document is a declarative description of a set of named rules. Build
+// compiles each entry's RuleNode into a staticRule via rules.BuildRule and
+// puts it in a rules.RuleSet under its map key; once that set is installed
+// in a rules.RuleLibrary, each rule can be referenced by name from
+// elsewhere (including from other rules in the same document, via a
+// RuleNode with Type "ref").
+type Document struct {
+	Version int                 `yaml:"version" json:"version"`
+	Rules   map[string]RuleNode `yaml:"rules" json:"rules"`
+}
+
+// RuleNode mirrors rules.RuleSpec in a form convenient for YAML/JSON: a
+// boolean expression tree over "equals", "equalsLiteral", "lessThan",
+// "greaterThan", "regex", "exists", "numericEquals", "and", "or", "xor",
+// "nand", "not", and "ref" nodes.
+type RuleNode struct {
+	Type     string     `yaml:"type" json:"type"`
+	Keys     []string   `yaml:"keys,omitempty" json:"keys,omitempty"`
+	Value    *string    `yaml:"value,omitempty" json:"value,omitempty"`
+	Pattern  string     `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Ref      string     `yaml:"ref,omitempty" json:"ref,omitempty"`
+	Children []RuleNode `yaml:"children,omitempty" json:"children,omitempty"`
+}
+
+// ParseYAML parses a YAML rule document.
+func ParseYAML(data []byte) (*Document, error) {
+	doc := Document{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("spec: invalid YAML rule document: %s", err)
+	}
+	return &doc, nil
+}
+
+// ParseJSON parses a JSON rule document.
+func ParseJSON(data []byte) (*Document, error) {
+	doc := Document{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("spec: invalid JSON rule document: %s", err)
+	}
+	return &doc, nil
+}
+
+func toRuleSpec(node RuleNode) rules.RuleSpec {
+	children := make([]rules.RuleSpec, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, toRuleSpec(child))
+	}
+	return rules.RuleSpec{
+		Type:     node.Type,
+		Keys:     node.Keys,
+		Value:    node.Value,
+		Pattern:  node.Pattern,
+		Ref:      node.Ref,
+		Children: children,
+	}
+}
+
+// Build compiles every rule in doc into a rules.RuleSet. library is used
+// only to resolve "ref" nodes against rules that will eventually live in
+// it; Build does not mutate library itself, so a caller can build a whole
+// document and only install it (e.g. via library.ReplaceAll) once the
+// build succeeds in full, avoiding a torn ruleset. attr is applied to
+// every built rule.
+func Build(doc *Document, attr rules.Attributes, library *rules.RuleLibrary) (rules.RuleSet, error) {
+	ruleSet := rules.NewRuleSet()
+	for name, node := range doc.Rules {
+		rule, err := rules.BuildRule(toRuleSpec(node), attr, library)
+		if err != nil {
+			return nil, fmt.Errorf("spec: rule %q: %s", name, err)
+		}
+		ruleSet.Set(name, rule)
+	}
+	return ruleSet, nil
+}
@@ -0,0 +1,85 @@
+package spec
+
+import (
+	"testing"
+
+	"github.com/mirskifa/go-etcd-rules/rules"
+)
+
+func TestParseYAML(t *testing.T) {
+	data := []byte("version: 1\nrules:\n  primary:\n    type: equalsLiteral\n    keys: [\"/primary\"]\n    value: \"1\"\n")
+	doc, err := ParseYAML(data)
+	if err != nil {
+		t.Fatalf("ParseYAML: unexpected error: %s", err)
+	}
+	if doc.Rules["primary"].Type != "equalsLiteral" {
+		t.Errorf("ParseYAML: got type %q, want %q", doc.Rules["primary"].Type, "equalsLiteral")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	data := []byte(`{"version":1,"rules":{"primary":{"type":"equalsLiteral","keys":["/primary"],"value":"1"}}}`)
+	doc, err := ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON: unexpected error: %s", err)
+	}
+	if doc.Rules["primary"].Type != "equalsLiteral" {
+		t.Errorf("ParseJSON: got type %q, want %q", doc.Rules["primary"].Type, "equalsLiteral")
+	}
+}
+
+func TestBuildPropagatesErrorInsteadOfPanicking(t *testing.T) {
+	value := "x"
+	// A malformed document like this is reachable straight from an etcd
+	// prefix via DynamicRuleLoader; Build must surface it as an error so
+	// the loader can report it, not panic the loader goroutine.
+	doc := &Document{Rules: map[string]RuleNode{
+		"broken": {Type: "equalsLiteral", Value: &value}, // no Keys
+	}}
+	library := rules.NewRuleLibrary()
+	if _, err := Build(doc, nil, library); err == nil {
+		t.Fatal("Build with a keyless equalsLiteral node: expected error, got nil")
+	}
+}
+
+func TestBuildPropagatesErrorForUnknownType(t *testing.T) {
+	doc := &Document{Rules: map[string]RuleNode{
+		"broken": {Type: "not-a-real-type"},
+	}}
+	library := rules.NewRuleLibrary()
+	if _, err := Build(doc, nil, library); err == nil {
+		t.Fatal("Build with an unknown rule type: expected error, got nil")
+	}
+}
+
+// TestFailedBuildLeavesPriorRulesetInstalled models the loader's own
+// contract: Build never mutates the library it's given, so a caller that
+// only installs the result via library.ReplaceAll after a successful Build
+// (as DynamicRuleLoader.loadSnapshot does) never tears down a good ruleset
+// in favor of a half-built bad one.
+func TestFailedBuildLeavesPriorRulesetInstalled(t *testing.T) {
+	library := rules.NewRuleLibrary()
+	goodValue := "1"
+	goodDoc := &Document{Rules: map[string]RuleNode{
+		"primary": {Type: "equalsLiteral", Keys: []string{"/primary"}, Value: &goodValue},
+	}}
+	goodSet, err := Build(goodDoc, nil, library)
+	if err != nil {
+		t.Fatalf("Build(goodDoc): unexpected error: %s", err)
+	}
+	library.ReplaceAll(goodSet)
+
+	badDoc := &Document{Rules: map[string]RuleNode{
+		"broken": {Type: "not-a-real-type"},
+	}}
+	if _, err := Build(badDoc, nil, library); err == nil {
+		t.Fatal("Build(badDoc): expected error, got nil")
+	}
+
+	// "primary" must still be resolvable: a failed Build must not have
+	// torn down what ReplaceAll installed.
+	refSpec := rules.RuleSpec{Type: "ref", Ref: "primary"}
+	if _, err := rules.BuildRule(refSpec, nil, library); err != nil {
+		t.Fatalf("ref to %q after a failed reload: unexpected error: %s", "primary", err)
+	}
+}
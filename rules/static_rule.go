@@ -10,10 +10,114 @@ type staticRule interface {
 	satisfiable(key string, value *string) bool
 	satisfied(api readAPI) (bool, error)
 	getAttributes() Attributes
+	// requiredKeys returns every etcd key this rule (and anything nested
+	// inside it) reads from satisfied, so callers can batch them into a
+	// single getMulti instead of reading one key at a time.
+	requiredKeys() []string
 }
 
 type readAPI interface {
 	get(string) (*string, error)
+	// getMulti reads several keys at once, returning a map keyed by each
+	// requested key (with a nil value for keys that aren't set). It
+	// exists so a compound rule touching many keys can collapse what
+	// would otherwise be one etcd round-trip per key into one round-trip
+	// total.
+	getMulti(keys []string) (map[string]*string, error)
+}
+
+// cachedReadAPI wraps a readAPI with a set of already-fetched key/value
+// pairs, so that nested rules sharing those keys don't each issue their own
+// etcd read for them.
+type cachedReadAPI struct {
+	api    readAPI
+	cached map[string]*string
+}
+
+func (cra *cachedReadAPI) get(key string) (*string, error) {
+	if value, ok := cra.cached[key]; ok {
+		return value, nil
+	}
+	return cra.api.get(key)
+}
+
+func (cra *cachedReadAPI) getMulti(keys []string) (map[string]*string, error) {
+	values := make(map[string]*string, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if value, ok := cra.cached[key]; ok {
+			values[key] = value
+			continue
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) > 0 {
+		fetched, err := cra.api.getMulti(missing)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fetched {
+			values[key] = value
+			cra.cached[key] = value
+		}
+	}
+	return values, nil
+}
+
+// prefetch wraps api with a cachedReadAPI pre-populated with keys read via
+// a single getMulti call. It returns api unchanged when keys is empty.
+func prefetch(api readAPI, keys []string) (readAPI, error) {
+	if len(keys) == 0 {
+		return api, nil
+	}
+	values, err := api.getMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedReadAPI{api: api, cached: values}, nil
+}
+
+// unionRequiredKeys returns the deduplicated union of requiredKeys across
+// rules.
+func unionRequiredKeys(rules []staticRule) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, rule := range rules {
+		for _, key := range rule.requiredKeys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// intersectRequiredKeys returns the keys required by every one of rules,
+// i.e. the keys safe to prefetch without undermining an OR's short-circuit
+// semantics for branch-specific keys.
+func intersectRequiredKeys(rules []staticRule) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, rule := range rules {
+		seenInRule := map[string]bool{}
+		for _, key := range rule.requiredKeys() {
+			if seenInRule[key] {
+				continue
+			}
+			seenInRule[key] = true
+			counts[key]++
+		}
+	}
+	var shared []string
+	for key, count := range counts {
+		if count == len(rules) {
+			shared = append(shared, key)
+		}
+	}
+	return shared
 }
 
 type baseRule struct {
@@ -78,6 +182,10 @@ func (elr *equalsLiteralRule) keyMatch(key string) bool {
 	return elr.key == key
 }
 
+func (elr *equalsLiteralRule) requiredKeys() []string {
+	return []string{elr.key}
+}
+
 type compoundStaticRule struct {
 	nestedRules []staticRule
 }
@@ -106,13 +214,24 @@ func (csr *compoundStaticRule) keyMatch(key string) bool {
 	return false
 }
 
+func (csr *compoundStaticRule) requiredKeys() []string {
+	return unionRequiredKeys(csr.nestedRules)
+}
+
 type andStaticRule struct {
 	compoundStaticRule
 }
 
 func (asr *andStaticRule) satisfied(api readAPI) (bool, error) {
+	// An AND visits every branch whenever it ends up satisfied, and stops
+	// early only at the first unsatisfied branch, so prefetch everything
+	// any branch might read.
+	cachedAPI, err := prefetch(api, asr.requiredKeys())
+	if err != nil {
+		return false, err
+	}
 	for _, rule := range asr.nestedRules {
-		satisfied, err := rule.satisfied(api)
+		satisfied, err := rule.satisfied(cachedAPI)
 		if err != nil {
 			return false, err
 		}
@@ -128,12 +247,73 @@ type orStaticRule struct {
 }
 
 func (osr *orStaticRule) satisfied(api readAPI) (bool, error) {
+	// An OR can short-circuit, so only prefetch keys every branch needs;
+	// keys specific to one branch are still read lazily so a branch that's
+	// never reached never pays for them.
+	cachedAPI, err := prefetch(api, intersectRequiredKeys(osr.nestedRules))
+	if err != nil {
+		return false, err
+	}
 	for _, rule := range osr.nestedRules {
+		satisfied, err := rule.satisfied(cachedAPI)
+		if err != nil {
+			return false, err
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// xorStaticRule is satisfied when exactly one of its nested rules is
+// satisfied, e.g. to express mutual-exclusion invariants like "exactly one
+// primary".
+type xorStaticRule struct {
+	compoundStaticRule
+}
+
+func (xsr *xorStaticRule) satisfiable(key string, value *string) bool {
+	// XOR is non-monotone: a branch going from satisfied to unsatisfied
+	// can flip XOR from false to true, so "no branch reports satisfiable"
+	// is not proof XOR can't change. Fall back to the conservative
+	// "this key is involved at all" check, same as the fixed NOT.
+	return xsr.keyMatch(key)
+}
+
+func (xsr *xorStaticRule) satisfied(api readAPI) (bool, error) {
+	trueCount := 0
+	for _, rule := range xsr.nestedRules {
 		satisfied, err := rule.satisfied(api)
 		if err != nil {
 			return false, err
 		}
 		if satisfied {
+			trueCount++
+		}
+	}
+	return trueCount == 1, nil
+}
+
+// nandStaticRule is satisfied unless every nested rule is satisfied.
+type nandStaticRule struct {
+	compoundStaticRule
+}
+
+func (nar *nandStaticRule) satisfiable(key string, value *string) bool {
+	// Same reasoning as xorStaticRule.satisfiable: NAND is non-monotone
+	// (the last branch going from satisfied to unsatisfied flips NAND
+	// from false to true), so fall back to the conservative check.
+	return nar.keyMatch(key)
+}
+
+func (nar *nandStaticRule) satisfied(api readAPI) (bool, error) {
+	for _, rule := range nar.nestedRules {
+		satisfied, err := rule.satisfied(api)
+		if err != nil {
+			return false, err
+		}
+		if !satisfied {
 			return true, nil
 		}
 	}
@@ -152,8 +332,27 @@ func (nsr *notStaticRule) keyMatch(key string) bool {
 	return nsr.nested.keyMatch(key)
 }
 
+func (nsr *notStaticRule) requiredKeys() []string {
+	return nsr.nested.requiredKeys()
+}
+
 func (nsr *notStaticRule) satisfiable(key string, value *string) bool {
-	return nsr.nested.keyMatch(key)
+	if !nsr.nested.keyMatch(key) {
+		return false
+	}
+	// For leaf rules, satisfiable(key, value) exactly predicts whether
+	// satisfied would return true for this key/value, so NOT can safely
+	// negate it. Compound rules only return a conservative
+	// over-approximation (true if *any* branch could end up satisfied),
+	// so negating them could make NOT miss a change; fall back to the old
+	// conservative "watch it" behavior for those.
+	switch nsr.nested.(type) {
+	case *equalsLiteralRule, *existsRule, *regexMatchRule, *numericEqualsRule,
+		*lessThanLiteralRule, *greaterThanLiteralRule:
+		return !nsr.nested.satisfiable(key, value)
+	default:
+		return true
+	}
 }
 
 func (nsr *notStaticRule) satisfied(api readAPI) (bool, error) {
@@ -185,24 +384,25 @@ func (er *equalsRule) keyMatch(key string) bool {
 	return false
 }
 
+func (er *equalsRule) requiredKeys() []string {
+	return er.keys
+}
+
 func (er *equalsRule) satisfied(api readAPI) (bool, error) {
 	if len(er.keys) == 0 {
 		return true, nil
 	}
-	ref, err1 := api.get(er.keys[0])
-	// Failed to get reference value?
-	if err1 != nil {
-		return false, err1
+	// One batched read instead of len(er.keys) serial ones.
+	values, err := api.getMulti(er.keys)
+	if err != nil {
+		return false, err
 	}
+	ref := values[er.keys[0]]
 	for index, key := range er.keys {
 		if index == 0 {
 			continue
 		}
-		// Failed to get next value?
-		value, err2 := api.get(key)
-		if err2 != nil {
-			return false, err2
-		}
+		value := values[key]
 		// Value is nil
 		if value == nil {
 			// Reference value isn't
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+)
+
+// countingReadAPI counts how many etcd round-trips satisfied() causes,
+// treating each get and each getMulti call as one round-trip regardless of
+// how many keys it carries.
+type countingReadAPI struct {
+	values     map[string]*string
+	roundTrips int
+}
+
+func (c *countingReadAPI) get(key string) (*string, error) {
+	c.roundTrips++
+	return c.values[key], nil
+}
+
+func (c *countingReadAPI) getMulti(keys []string) (map[string]*string, error) {
+	c.roundTrips++
+	values := make(map[string]*string, len(keys))
+	for _, key := range keys {
+		values[key] = c.values[key]
+	}
+	return values, nil
+}
+
+// buildWideAndRule returns an andStaticRule over keyCount equalsLiteral
+// rules, each over its own key, to model a rule with many required keys.
+func buildWideAndRule(keyCount int) staticRule {
+	literal := "1"
+	nested := make([]staticRule, 0, keyCount)
+	for i := 0; i < keyCount; i++ {
+		factory := newEqualsLiteralRuleFactory(&literal)
+		nested = append(nested, factory.newRule([]string{fmt.Sprintf("/key/%d", i)}, nil))
+	}
+	return &andStaticRule{compoundStaticRule{nestedRules: nested}}
+}
+
+// BenchmarkAndStaticRuleSatisfied_RoundTrips reports the number of etcd
+// round-trips a 10-key AND rule causes per satisfied() call, which should
+// be a single batched getMulti rather than 10 serial gets.
+func BenchmarkAndStaticRuleSatisfied_RoundTrips(b *testing.B) {
+	const keyCount = 10
+	rule := buildWideAndRule(keyCount)
+	literal := "1"
+	values := make(map[string]*string, keyCount)
+	for i := 0; i < keyCount; i++ {
+		values[fmt.Sprintf("/key/%d", i)] = &literal
+	}
+
+	api := &countingReadAPI{values: values}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		api.roundTrips = 0
+		if _, err := rule.satisfied(api); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(api.roundTrips), "round-trips/op")
+}
@@ -0,0 +1,62 @@
+package rules
+
+import "testing"
+
+func TestSubRuleReferenceCycleThroughCompound(t *testing.T) {
+	library := NewRuleLibrary()
+	ref := &subRuleReference{name: "A", library: library}
+	compound := &andStaticRule{compoundStaticRule{nestedRules: []staticRule{ref}}}
+	library.SetRule("A", compound)
+
+	if _, err := ref.resolve(map[string]bool{}); err == nil {
+		t.Fatal("resolve: expected a cyclical sub-rule reference error, got nil")
+	}
+
+	api := &countingReadAPI{values: map[string]*string{}}
+	if _, err := ref.satisfied(api); err == nil {
+		t.Fatal("satisfied: expected a cyclical sub-rule reference error, got nil")
+	}
+}
+
+func TestNotStaticRuleSatisfiable(t *testing.T) {
+	literal := "prod"
+	nested := (&equalsLiteralRuleFactory{value: &literal}).newRule([]string{"/env"}, nil)
+	notRule := &notStaticRule{nested: nested}
+
+	matching := "prod"
+	if notRule.satisfiable("/env", &matching) {
+		t.Error("NOT(equalsLiteral) satisfiable on a matching PUT: want false, since NOT cannot become satisfied")
+	}
+
+	mismatching := "staging"
+	if !notRule.satisfiable("/env", &mismatching) {
+		t.Error("NOT(equalsLiteral) satisfiable on a mismatching PUT: want true")
+	}
+}
+
+func TestXorNandSatisfiableStaysConservativeAcrossFlip(t *testing.T) {
+	literalA := "1"
+	branchA := (&equalsLiteralRuleFactory{value: &literalA}).newRule([]string{"/a"}, nil)
+	literalB := "1"
+	branchB := (&equalsLiteralRuleFactory{value: &literalB}).newRule([]string{"/b"}, nil)
+	csr := compoundStaticRule{nestedRules: []staticRule{branchA, branchB}}
+	xorRule := &xorStaticRule{csr}
+	nandRule := &nandStaticRule{csr}
+
+	// B flips from satisfied ("1") to unsatisfied ("0"). No branch's
+	// satisfiable(key, value) is true for this PUT (equalsLiteralRule
+	// reports false on a mismatch), yet XOR/NAND can still change as a
+	// result, so satisfiable must conservatively stay true for any key the
+	// rule watches rather than relying on a branch reporting true.
+	off := "0"
+	if !xorRule.satisfiable("/b", &off) {
+		t.Error("xorStaticRule.satisfiable: want true (conservative) on a branch flipping satisfied->unsatisfied")
+	}
+	if !nandRule.satisfiable("/b", &off) {
+		t.Error("nandStaticRule.satisfiable: want true (conservative) on a branch flipping satisfied->unsatisfied")
+	}
+
+	if xorRule.satisfiable("/unrelated", &off) {
+		t.Error("xorStaticRule.satisfiable: want false for a key the rule doesn't watch")
+	}
+}
@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RuleLibrary holds named rules that can be referenced from elsewhere in a
+// rule tree via subRuleReference. It lets callers build large shared
+// predicates (e.g. "is-production", "is-leader-node") once and reuse them
+// from many watch rules instead of inlining them everywhere.
+type RuleLibrary struct {
+	mutex sync.RWMutex
+	rules map[string]staticRule
+}
+
+// NewRuleLibrary creates an empty rule library.
+func NewRuleLibrary() *RuleLibrary {
+	return &RuleLibrary{
+		rules: map[string]staticRule{},
+	}
+}
+
+// SetRule registers the rule under name, replacing any existing rule
+// registered under the same name. It is safe to call concurrently with
+// rule evaluation.
+func (rl *RuleLibrary) SetRule(name string, rule staticRule) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.rules[name] = rule
+}
+
+// ReplaceAll atomically swaps the entire set of named rules, so that
+// concurrent evaluation never sees a mix of rules from two different
+// document versions.
+func (rl *RuleLibrary) ReplaceAll(ruleSet RuleSet) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.rules = map[string]staticRule(ruleSet)
+}
+
+func (rl *RuleLibrary) getRule(name string) (staticRule, bool) {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+	rule, ok := rl.rules[name]
+	return rule, ok
+}
+
+// subRuleReference delegates to a rule looked up by name in a RuleLibrary
+// at evaluation time, rather than inlining the nested rule. This allows a
+// rule tree to reference another named rule instead of always composing
+// sub-expressions through andStaticRule/orStaticRule/notStaticRule.
+type subRuleReference struct {
+	baseRule
+	name    string
+	library *RuleLibrary
+}
+
+type subRuleReferenceFactory struct {
+	name    string
+	library *RuleLibrary
+}
+
+func newSubRuleReferenceFactory(name string, library *RuleLibrary) ruleFactory {
+	factory := subRuleReferenceFactory{
+		name:    name,
+		library: library,
+	}
+	return &factory
+}
+
+func (srrf *subRuleReferenceFactory) newRule(keys []string, attr Attributes) staticRule {
+	br := baseRule{
+		attr: attr,
+	}
+	r := subRuleReference{
+		baseRule: br,
+		name:     srrf.name,
+		library:  srrf.library,
+	}
+	return &r
+}
+
+// resolve follows named references until it finds the underlying rule,
+// returning an error if name is unresolved or a cycle is detected. The
+// cycle check also looks through and/or/xor/nand/not wrappers, since a
+// reference can loop back to an ancestor indirectly, e.g. name "A"
+// resolving to an andStaticRule that itself nests a reference to "A".
+func (srr *subRuleReference) resolve(visited map[string]bool) (staticRule, error) {
+	return resolveName(srr.name, srr.library, visited)
+}
+
+// resolveName looks up name in library, then walks the resolved rule (and
+// anything it transitively contains) to confirm resolving it can't loop
+// back to a name already in visited before returning it.
+func resolveName(name string, library *RuleLibrary, visited map[string]bool) (staticRule, error) {
+	if visited[name] {
+		return nil, fmt.Errorf("rules: cyclical sub-rule reference detected for %q", name)
+	}
+	visited[name] = true
+	rule, ok := library.getRule(name)
+	if !ok {
+		return nil, fmt.Errorf("rules: no rule registered under name %q", name)
+	}
+	if nested, ok := rule.(*subRuleReference); ok {
+		return nested.resolve(visited)
+	}
+	if err := checkForCycles(rule, visited); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// checkForCycles walks rule looking for nested subRuleReference nodes
+// (through and/or/xor/nand/not) and confirms each can be resolved without
+// looping back to a name in visited. It doesn't mutate visited itself;
+// each reference it finds is checked against its own copy so that sibling
+// branches of a compound rule don't spuriously interfere with each other.
+func checkForCycles(rule staticRule, visited map[string]bool) error {
+	if ref, ok := rule.(*subRuleReference); ok {
+		_, err := resolveName(ref.name, ref.library, copyVisited(visited))
+		return err
+	}
+	switch r := rule.(type) {
+	case *andStaticRule:
+		return checkChildrenForCycles(r.nestedRules, visited)
+	case *orStaticRule:
+		return checkChildrenForCycles(r.nestedRules, visited)
+	case *xorStaticRule:
+		return checkChildrenForCycles(r.nestedRules, visited)
+	case *nandStaticRule:
+		return checkChildrenForCycles(r.nestedRules, visited)
+	case *notStaticRule:
+		return checkForCycles(r.nested, visited)
+	default:
+		return nil
+	}
+}
+
+func checkChildrenForCycles(children []staticRule, visited map[string]bool) error {
+	for _, child := range children {
+		if err := checkForCycles(child, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyVisited(visited map[string]bool) map[string]bool {
+	copied := make(map[string]bool, len(visited))
+	for name := range visited {
+		copied[name] = true
+	}
+	return copied
+}
+
+func (srr *subRuleReference) keyMatch(key string) bool {
+	rule, err := srr.resolve(map[string]bool{})
+	if err != nil {
+		return false
+	}
+	return rule.keyMatch(key)
+}
+
+func (srr *subRuleReference) requiredKeys() []string {
+	rule, err := srr.resolve(map[string]bool{})
+	if err != nil {
+		return nil
+	}
+	return rule.requiredKeys()
+}
+
+func (srr *subRuleReference) satisfiable(key string, value *string) bool {
+	rule, err := srr.resolve(map[string]bool{})
+	if err != nil {
+		return false
+	}
+	return rule.satisfiable(key, value)
+}
+
+func (srr *subRuleReference) satisfied(api readAPI) (bool, error) {
+	rule, err := srr.resolve(map[string]bool{})
+	if err != nil {
+		return false, err
+	}
+	return rule.satisfied(api)
+}